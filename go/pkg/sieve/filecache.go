@@ -0,0 +1,119 @@
+package sieve
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// FileBackedSieve is a SegmentedSieve that persists its computed primes to
+// disk, so a second process (or a later call for a larger n) can resume
+// sieving from s.max instead of starting over from 2. The on-disk format is
+// a header with max followed by a bit-packed bitmap over the wheel
+// candidates in [0, max], one bit per candidate: 48 bits per 210-block,
+// rather than one bit per integer, since every non-candidate is implied
+// composite and not worth storing.
+type FileBackedSieve struct {
+	*SegmentedSieve
+	path string
+}
+
+// NewFileBackedSieve opens path and loads any primes already cached there.
+// A missing file is not an error: the sieve just starts out the same as one
+// from NewSieve, and the cache is created the first time Flush is called.
+func NewFileBackedSieve(path string) (*FileBackedSieve, error) {
+	s := &FileBackedSieve{
+		SegmentedSieve: NewSieveWithOptions(SieveOptions{}).(*SegmentedSieve),
+		path:           path,
+	}
+	if err := s.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// wheelBitmapSize returns how many bits (and bytes) are needed to store one
+// bit per wheel candidate in [0, max].
+func wheelBitmapSize(max int64) (bits, bytes int64) {
+	blocks := max/wheelModulus + 1
+	bits = blocks * int64(len(wheelResidues))
+	bytes = (bits + 7) / 8
+	return bits, bytes
+}
+
+// Load replaces s's in-memory primes with whatever is stored at s.path.
+func (s *FileBackedSieve) Load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var max int64
+	if err := binary.Read(f, binary.BigEndian, &max); err != nil {
+		return err
+	}
+
+	_, size := wheelBitmapSize(max)
+	bitmap := make([]byte, size)
+	if _, err := io.ReadFull(f, bitmap); err != nil {
+		return err
+	}
+
+	primes := []int64{2, 3, 5, 7}
+	bit := int64(0)
+	for k := int64(0); k <= max/wheelModulus; k++ {
+		for _, r := range wheelResidues {
+			candidate := wheelModulus*k + r
+			if candidate <= max && bitmap[bit/8]&(1<<uint(bit%8)) != 0 {
+				primes = append(primes, candidate)
+			}
+			bit++
+		}
+	}
+
+	s.mu.Lock()
+	s.primes = primes
+	s.max = max
+	s.mu.Unlock()
+	return nil
+}
+
+// Flush writes s's current primes to s.path, overwriting whatever was
+// there before.
+func (s *FileBackedSieve) Flush() error {
+	s.mu.Lock()
+	primes, max := s.primes, s.max
+	s.mu.Unlock()
+
+	f, err := os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := binary.Write(f, binary.BigEndian, max); err != nil {
+		return err
+	}
+
+	primeSet := make(map[int64]bool, len(primes))
+	for _, p := range primes {
+		primeSet[p] = true
+	}
+
+	_, size := wheelBitmapSize(max)
+	bitmap := make([]byte, size)
+	bit := int64(0)
+	for k := int64(0); k <= max/wheelModulus; k++ {
+		for _, r := range wheelResidues {
+			candidate := wheelModulus*k + r
+			if candidate <= max && primeSet[candidate] {
+				bitmap[bit/8] |= 1 << uint(bit%8)
+			}
+			bit++
+		}
+	}
+
+	_, err = f.Write(bitmap)
+	return err
+}
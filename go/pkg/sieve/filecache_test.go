@@ -0,0 +1,54 @@
+package sieve
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileBackedSieveFlushAndLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "primes.cache")
+
+	s, err := NewFileBackedSieve(path)
+	if err != nil {
+		t.Fatalf("NewFileBackedSieve: %v", err)
+	}
+	// Grow the cache well past the seeded primes before persisting it.
+	s.NthPrime(100)
+	want := []int64{}
+	for n := int64(2); n <= 500; n++ {
+		if isPrimeBruteForce(n) {
+			want = append(want, n)
+		}
+	}
+
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	reloaded, err := NewFileBackedSieve(path)
+	if err != nil {
+		t.Fatalf("NewFileBackedSieve (reload): %v", err)
+	}
+
+	got := reloaded.PrimesInRange(2, 500)
+	if len(got) != len(want) {
+		t.Fatalf("reloaded PrimesInRange(2, 500) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("reloaded PrimesInRange(2, 500) = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNewFileBackedSieveMissingFileStartsEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.cache")
+
+	s, err := NewFileBackedSieve(path)
+	if err != nil {
+		t.Fatalf("NewFileBackedSieve with a missing file: %v", err)
+	}
+	if got := s.NthPrime(0); got != 2 {
+		t.Errorf("NthPrime(0) = %d, want 2", got)
+	}
+}
@@ -1,28 +1,73 @@
 package sieve
 
 import (
+	"context"
 	"math"
+	"runtime"
+	"sync"
 )
 
 type Sieve interface {
 	NthPrime(n int64) int64
+	// PrimesInRange returns every prime in [lo, hi].
+	PrimesInRange(lo, hi int64) []int64
+	// PrimePi returns the number of primes <= x (the prime-counting
+	// function, traditionally written pi(x)).
+	PrimePi(x int64) int64
+}
+
+// SieveOptions configures a SegmentedSieve's internals: how large each
+// segment is, how many workers sieve segments concurrently, and a Context
+// that can cancel a long-running NthPrime call.
+type SieveOptions struct {
+	// SegmentSize overrides the default sqrt(n)-sized segments. Zero means
+	// "use the default".
+	SegmentSize int64
+	// Workers is the number of goroutines sieving segments concurrently.
+	// Zero means GOMAXPROCS.
+	Workers int
+	// Context, if set, is checked between segments so a long sieve can be
+	// cancelled instead of run to completion.
+	Context context.Context
 }
 
 func NewSieve() Sieve {
+	return NewSieveWithOptions(SieveOptions{})
+}
+
+// NewSieveWithOptions is like NewSieve but lets the caller tune segment
+// size, worker count and cancellation via opts.
+func NewSieveWithOptions(opts SieveOptions) Sieve {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
 	// Priming the sieve with the first few primes. This is required
 	// because the calculated upper bound only applies for n>6
 	return &SegmentedSieve{
-		primes: []int64{2, 3, 5, 7, 11, 13, 17},
-		max:    17,
+		primes:  []int64{2, 3, 5, 7, 11, 13, 17},
+		max:     17,
+		options: opts,
 	}
 }
 
 type SegmentedSieve struct {
-	primes []int64
-	max    int64
+	// mu guards primes and max. Every exported method that reads or
+	// grows them takes mu for its whole body; the unexported helpers
+	// below (sieve, ensureBasePrimes) assume the caller already holds
+	// it, since they're only ever reached through one of those methods.
+	mu      sync.Mutex
+	primes  []int64
+	max     int64
+	options SieveOptions
 }
 
 func (s *SegmentedSieve) NthPrime(n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Calculating every prime up through the nth prime is not the most
 	// efficient way of finding just the nth prime. However, if we need
 	// to find many primes in general, the sieve approach will give us a
@@ -41,58 +86,341 @@ func (s *SegmentedSieve) NthPrime(n int64) int64 {
 		// https://projecteuclid.org/journals/illinois-journal-of-mathematics/volume-6/issue-1/Approximate-formulas-for-some-functions-of-prime-numbers/10.1215/ijm/1255631807.full
 		nf := float64(n)
 		bound := int64(math.Ceil(nf * (math.Log(nf) + math.Log(math.Log(nf)))))
-		s.sieve(bound)
+		for n >= int64(len(s.primes)) {
+			s.sieve(bound)
+			if n < int64(len(s.primes)) {
+				break
+			}
+			if s.options.Context.Err() != nil {
+				// The Context was actually cancelled before we reached
+				// the nth prime. Should really return an error here too,
+				// but sticking to the predefined method signature, so -1
+				// signals "not found" instead.
+				return -1
+			}
+			// The bound above is only an asymptotic upper bound and can
+			// still come up short for small n in practice; double it and
+			// try again rather than indexing out of range.
+			bound *= 2
+		}
 	}
 	return s.primes[n]
 }
 
+// PrimesInRange returns every prime in [lo, hi] by sieving that range
+// directly, without materialising (or caching) every prime from 2 up to
+// hi the way NthPrime would. Only the base primes up to sqrt(hi), which
+// are cheap to keep around, get added to s.primes.
+func (s *SegmentedSieve) PrimesInRange(lo, hi int64) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lo < 2 {
+		lo = 2
+	}
+	if hi < lo {
+		return []int64{}
+	}
+
+	found := []int64{}
+	// sieveSegment is built around the 210-wheel, which excludes 2, 3, 5
+	// and 7 as candidates (not just their composites), so they have to be
+	// added by hand instead of delegated to it.
+	for _, p := range []int64{2, 3, 5, 7} {
+		if p >= lo && p <= hi {
+			found = append(found, p)
+		}
+	}
+
+	wheelLo := lo
+	if wheelLo < 11 {
+		wheelLo = 11
+	}
+	if wheelLo > hi {
+		return found
+	}
+
+	base := int64(math.Ceil(math.Sqrt(float64(hi))))
+	s.ensureBasePrimes(base)
+
+	segmentSize := s.options.SegmentSize
+	if segmentSize <= 0 {
+		segmentSize = base
+	}
+
+	// Route through the same worker-pool dispatch sieve() uses, rather
+	// than sieving sequentially, so range queries benefit from
+	// s.options.Workers and can be cancelled via s.options.Context.
+	_, results := s.dispatchSegments(wheelLo, hi, segmentSize)
+	for _, primes := range results {
+		if primes == nil {
+			// The Context was cancelled before this segment was sieved.
+			break
+		}
+		found = append(found, primes...)
+	}
+	return found
+}
+
+// PrimePi returns the number of primes <= x, counting them segment by
+// segment instead of caching them, so x in the 10^11+ range can be
+// evaluated without holding every one of its primes in memory at once.
+func (s *SegmentedSieve) PrimePi(x int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if x < 2 {
+		return 0
+	}
+
+	base := int64(math.Ceil(math.Sqrt(float64(x))))
+	s.ensureBasePrimes(base)
+
+	var count int64
+	for _, p := range s.primes {
+		if p > x {
+			break
+		}
+		count++
+	}
+	if s.max >= x {
+		return count
+	}
+
+	segmentSize := s.options.SegmentSize
+	if segmentSize <= 0 {
+		segmentSize = base
+	}
+
+	// Same reasoning as PrimesInRange: reuse the worker-pool dispatch so
+	// a PrimePi(1e11)-class call can use every configured worker and
+	// honors cancellation, instead of sieving one segment at a time. The
+	// range is dispatched one window of Workers segments at a time, so only
+	// that window's primes are held before they are counted and dropped.
+	window := segmentSize * int64(s.options.Workers)
+	for lo := s.max + 1; lo <= x; {
+		hi := min(x, lo+window)
+		_, results := s.dispatchSegments(lo, hi, segmentSize)
+		for _, primes := range results {
+			if primes == nil {
+				// The Context was cancelled before this segment was sieved.
+				return count
+			}
+			count += int64(len(primes))
+		}
+		lo = hi + 1
+	}
+	return count
+}
+
+// PrimeStream is a lazy, unbounded view over the primes known to a
+// SegmentedSieve. Unlike NthPrime, it doesn't require the caller to know
+// how many primes they'll need up front: each call to Next grows the
+// underlying sieve's segment window on demand, doubling it so the number
+// of growths stays logarithmic in the number of primes consumed. Several
+// streams (and NthPrime calls) can be interleaved freely, including from
+// different goroutines, since they all go through the same SegmentedSieve's
+// mutex to reach its cached primes.
+type PrimeStream struct {
+	sieve *SegmentedSieve
+	index int64
+}
+
+// Primes returns a PrimeStream over s's primes in increasing order,
+// starting from the first one.
+func (s *SegmentedSieve) Primes() *PrimeStream {
+	return &PrimeStream{sieve: s}
+}
+
+// Next returns the next prime in the stream.
+func (p *PrimeStream) Next() int64 {
+	p.sieve.mu.Lock()
+	defer p.sieve.mu.Unlock()
+
+	for p.index >= int64(len(p.sieve.primes)) {
+		bound := p.sieve.max * 2
+		if bound <= p.sieve.max {
+			// max starts at 0 only in the zero-value SegmentedSieve case;
+			// NewSieve already primes it to 17.
+			bound = p.sieve.max + 1
+		}
+		p.sieve.sieve(bound)
+	}
+	prime := p.sieve.primes[p.index]
+	p.index++
+	return prime
+}
+
+// ensureBasePrimes grows s.primes/s.max to include every prime up through
+// upTo, if it doesn't already. Both sieve and the direct range queries
+// below need all primes up to sqrt(n) on hand before they can filter
+// anything past it.
+func (s *SegmentedSieve) ensureBasePrimes(upTo int64) {
+	if s.max < upTo {
+		newPrimes := initialSieve(s.max+1, upTo, s.primes)
+		s.primes = append(s.primes, newPrimes...)
+		s.max = upTo
+	}
+}
+
+// segmentJob is one [start, end] range to sieve, tagged with its position
+// in the overall job list so results can be written into a pre-allocated
+// slice instead of relying on channel read order for ordering.
+type segmentJob struct {
+	index      int
+	start, end int64
+}
+
+// dispatchSegments splits [start, end] into segmentSize-sized chunks and
+// sieves them across a fixed pool of s.options.Workers goroutines pulling
+// from a shared job channel, writing each result straight into its slot in
+// the returned results slice so ordering is preserved without making any
+// segment wait on whichever one happens to be slowest. Feeding stops early
+// if s.options.Context is cancelled, in which case the jobs past that
+// point are returned alongside a nil result.
+func (s *SegmentedSieve) dispatchSegments(start, end, segmentSize int64) ([]segmentJob, [][]int64) {
+	jobs := []segmentJob{}
+	for {
+		jobEnd := min(end, start+segmentSize)
+		jobs = append(jobs, segmentJob{index: len(jobs), start: start, end: jobEnd})
+		if jobEnd >= end {
+			break
+		}
+		start = jobEnd + 1
+	}
+
+	results := make([][]int64, len(jobs))
+	jobCh := make(chan segmentJob)
+	var wg sync.WaitGroup
+	for i := 0; i < s.options.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				results[job.index] = sieveSegment(job.start, job.end, s.primes)
+			}
+		}()
+	}
+feed:
+	for _, job := range jobs {
+		select {
+		case jobCh <- job:
+		case <-s.options.Context.Done():
+			break feed
+		}
+	}
+	close(jobCh)
+	wg.Wait()
+
+	return jobs, results
+}
+
 func (s *SegmentedSieve) sieve(n int64) {
 	// Several of the optimizations applied here come from the following paper
 	// https://research.cs.wisc.edu/techreports/1990/TR909.pdf
-	segmentSize := int64(math.Ceil(math.Sqrt(float64(n))))
-	if s.max < segmentSize {
-		// We need all primes up through sqrt(n) for filtering the remainder of the range
-		newPrimes := initialSieve(s.max+1, segmentSize, s.primes)
-		s.primes = append(s.primes, newPrimes...)
-		s.max = segmentSize
+	segmentSize := s.options.SegmentSize
+	if segmentSize <= 0 {
+		segmentSize = int64(math.Ceil(math.Sqrt(float64(n))))
+	}
+	// The base-prime bound must always cover sqrt(n) so sieveSegment has
+	// every prime it needs for crossing off composites, regardless of how small a
+	// caller-supplied SegmentSize happens to be: that only controls how
+	// work is chunked across workers, not how far the base primes reach.
+	s.ensureBasePrimes(int64(math.Ceil(math.Sqrt(float64(n)))))
+	if s.max >= n {
+		return
 	}
 
 	// Split the remainder of the range we're checking into smaller
 	// segments that we can process independently
-	channels := []chan []int64{}
-	start := s.max + 1
-	end := min(n, start+segmentSize)
-	for end < n {
-		channel := make(chan []int64)
-		channels = append(channels, channel)
-		go func(start, end int64) {
-			channel <- sieveSegment(start, end, s.primes)
-		}(start, end)
-		start = end + 1
-		end = min(n, start+segmentSize)
-	}
-	channel := make(chan []int64)
-	channels = append(channels, channel)
-	go func(start, end int64) {
-		channel <- sieveSegment(start, end, s.primes)
-	}(start, end)
-
-	// As long as we read the channels in order, the order
-	// of the found primes is preserved.
-	for _, channel := range channels {
-		newPrimes := <-channel
+	jobs, results := s.dispatchSegments(s.max+1, n, segmentSize)
+
+	// A nil entry means its job was never picked up because the Context
+	// was cancelled; stop there rather than appending a gap-filled result.
+	completedEnd := s.max
+	for i, newPrimes := range results {
+		if newPrimes == nil {
+			break
+		}
 		s.primes = append(s.primes, newPrimes...)
+		completedEnd = jobs[i].end
 	}
-	s.max = end
+	s.max = completedEnd
 }
 
-func sieveSegment(start, end int64, primes []int64) []int64 {
-	candidates := make([]int64, end-start+1)
-	for i := range candidates {
-		candidates[i] = start + int64(i)
+// wheelModulus and wheelResidues implement a 2*3*5*7=210 wheel: of the 210
+// residues mod wheelModulus, only these 48 are coprime to 2, 3, 5 and 7, so
+// every multiple of a smaller prime is skipped before the crossout loop ever
+// runs. This is the "extensible" wheel variant, enumerated once at package
+// init rather than hard-coded.
+const wheelModulus = 210
+
+var wheelResidues [48]int64
+
+func init() {
+	i := 0
+	for r := int64(1); r < wheelModulus; r++ {
+		if r%2 != 0 && r%3 != 0 && r%5 != 0 && r%7 != 0 {
+			wheelResidues[i] = r
+			i++
+		}
+	}
+}
+
+// segmentBits is a bitset over the integers in [start, start+len(bits)*64),
+// one bit per candidate, used to cross composites off a segment in place
+// instead of rebuilding a []int64 every time a prime is applied.
+type segmentBits struct {
+	start int64
+	bits  []uint64
+}
+
+func newSegmentBits(start, end int64) *segmentBits {
+	sb := &segmentBits{
+		start: start,
+		bits:  make([]uint64, (end-start)/64+1),
 	}
+	// Every bit starts out marked composite/excluded; the wheel then
+	// clears exactly the residues coprime to 2, 3, 5 and 7, leaving only
+	// those as live candidates for the crossout loop below.
+	for i := range sb.bits {
+		sb.bits[i] = ^uint64(0)
+	}
+	for k := start / wheelModulus; k <= end/wheelModulus; k++ {
+		for _, r := range wheelResidues {
+			candidate := wheelModulus*k + r
+			if candidate >= start && candidate <= end {
+				sb.clear(candidate)
+			}
+		}
+	}
+	return sb
+}
+
+func (sb *segmentBits) set(v int64) {
+	i := v - sb.start
+	sb.bits[i/64] |= 1 << uint(i%64)
+}
+
+func (sb *segmentBits) clear(v int64) {
+	i := v - sb.start
+	sb.bits[i/64] &^= 1 << uint(i%64)
+}
+
+func (sb *segmentBits) isSet(v int64) bool {
+	i := v - sb.start
+	return sb.bits[i/64]&(1<<uint(i%64)) != 0
+}
+
+func sieveSegment(start, end int64, primes []int64) []int64 {
+	bits := newSegmentBits(start, end)
 
 	for _, prime := range primes {
+		if prime < 11 {
+			// The wheel has already excluded every multiple of 2, 3, 5
+			// and 7, so there's nothing left for these primes to filter.
+			continue
+		}
 		if prime*prime > end {
 			// We've already checked all the smaller primes, so this and
 			// all subsequent primes cannot possibly divide any of the
@@ -101,17 +429,23 @@ func sieveSegment(start, end int64, primes []int64) []int64 {
 			// more primes than necessary because of prior executions.
 			break
 		}
-		// For each prime, we rebuild the page with only the remaining
-		// possible candidates so we don't have to "remove" a candidate
-		// more than once. We could shave off some time here by using
-		// a linked list instead and modifying it in place.
-		vetted := []int64{}
-		for _, candidate := range candidates {
-			if candidate%prime > 0 {
-				vetted = append(vetted, candidate)
-			}
+		// Cross off every multiple of prime directly, the way the
+		// classical algorithm does it, rather than testing every
+		// remaining candidate against it.
+		first := ((start + prime - 1) / prime) * prime
+		if first < prime*prime {
+			first = prime * prime
+		}
+		for m := first; m <= end; m += prime {
+			bits.set(m)
+		}
+	}
+
+	candidates := []int64{}
+	for v := start; v <= end; v++ {
+		if !bits.isSet(v) {
+			candidates = append(candidates, v)
 		}
-		candidates = vetted
 	}
 	return candidates
 }
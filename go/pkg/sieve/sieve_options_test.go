@@ -0,0 +1,58 @@
+package sieve
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNthPrimeKnownValues(t *testing.T) {
+	s := NewSieve()
+	cases := map[int64]int64{
+		0: 2, 1: 3, 6: 17, 9: 29, 11: 37, 24: 97,
+	}
+	for n, want := range cases {
+		if got := s.NthPrime(n); got != want {
+			t.Errorf("NthPrime(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestNthPrimeSmallNDoesNotReturnCancellationSentinel(t *testing.T) {
+	// n=9 and n=11 are known to trip up the Rosser-Schoenfeld bound for
+	// small n; NthPrime must grow the bound and retry rather than
+	// reporting -1 as if the (never-cancelled) context had been cancelled.
+	s := NewSieve()
+	for _, n := range []int64{9, 11} {
+		if got := s.NthPrime(n); got < 0 {
+			t.Errorf("NthPrime(%d) = %d, want a real prime, not a cancellation sentinel", n, got)
+		}
+	}
+}
+
+func TestNthPrimeReturnsSentinelOnActualCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := NewSieveWithOptions(SieveOptions{Context: ctx})
+
+	if got := s.NthPrime(1000); got != -1 {
+		t.Errorf("NthPrime with an already-cancelled context = %d, want -1", got)
+	}
+}
+
+func TestSieveWorkerPoolPreservesOrder(t *testing.T) {
+	// A tiny segment size with many workers forces several segments to be
+	// sieved concurrently; the results still have to come back in order.
+	s := NewSieveWithOptions(SieveOptions{SegmentSize: 50, Workers: 8})
+
+	got := s.NthPrime(500)
+
+	bruteForcePrimes := []int64{}
+	for n := int64(2); len(bruteForcePrimes) <= 500; n++ {
+		if isPrimeBruteForce(n) {
+			bruteForcePrimes = append(bruteForcePrimes, n)
+		}
+	}
+	if got != bruteForcePrimes[500] {
+		t.Errorf("NthPrime(500) with a small segment size and many workers = %d, want %d", got, bruteForcePrimes[500])
+	}
+}
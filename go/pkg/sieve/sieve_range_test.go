@@ -0,0 +1,42 @@
+package sieve
+
+import "testing"
+
+func TestPrimesInRangeIncludesSmallPrimes(t *testing.T) {
+	cases := []struct {
+		lo, hi int64
+		want   []int64
+	}{
+		{1, 50, []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47}},
+		{2, 2, []int64{2}},
+		{5, 5, []int64{5}},
+		{4, 10, []int64{5, 7}},
+	}
+
+	s := NewSieve()
+	for _, c := range cases {
+		got := s.PrimesInRange(c.lo, c.hi)
+		if len(got) != len(c.want) {
+			t.Errorf("PrimesInRange(%d, %d) = %v, want %v", c.lo, c.hi, got, c.want)
+			continue
+		}
+		for i := range c.want {
+			if got[i] != c.want[i] {
+				t.Errorf("PrimesInRange(%d, %d) = %v, want %v", c.lo, c.hi, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPrimePiKnownValues(t *testing.T) {
+	s := NewSieve()
+	cases := map[int64]int64{
+		0: 0, 1: 0, 2: 1, 10: 4, 100: 25, 500: 95,
+	}
+	for x, want := range cases {
+		if got := s.PrimePi(x); got != want {
+			t.Errorf("PrimePi(%d) = %d, want %d", x, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package sieve
+
+import "testing"
+
+// knownPrimesTo100 is the reference list used to check sieveSegment's
+// bitset crossout against brute-force trial division.
+var knownPrimesTo100 = []int64{
+	2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67,
+	71, 73, 79, 83, 89, 97,
+}
+
+func isPrimeBruteForce(n int64) bool {
+	if n < 2 {
+		return false
+	}
+	for d := int64(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSieveSegmentMatchesBruteForce(t *testing.T) {
+	// The range has to reach past 11*11=121 and 11*13=143 with base
+	// primes including 11 and 13, or every composite in range is already
+	// excluded by the wheel pre-pass alone and the crossout loop itself
+	// (bits.set(m) for primes >= 11) never gets exercised.
+	basePrimes := []int64{2, 3, 5, 7, 11, 13}
+	start, end := int64(18), int64(150)
+
+	got := sieveSegment(start, end, basePrimes)
+
+	want := []int64{}
+	for n := start; n <= end; n++ {
+		if isPrimeBruteForce(n) {
+			want = append(want, n)
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("sieveSegment(%d, %d) = %v, want %v", start, end, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sieveSegment(%d, %d) = %v, want %v", start, end, got, want)
+		}
+	}
+}
+
+func TestSegmentBitsSetClearIsSet(t *testing.T) {
+	sb := newSegmentBits(100, 200)
+
+	// 121 = 11*11 is coprime to 2, 3, 5 and 7, so the wheel pre-pass
+	// should have left it clear (a live candidate) to start with.
+	if sb.isSet(121) {
+		t.Fatalf("121 should start clear (it's a wheel candidate)")
+	}
+
+	sb.set(121)
+	if !sb.isSet(121) {
+		t.Fatalf("121 should be set after explicit set")
+	}
+
+	sb.clear(121)
+	if sb.isSet(121) {
+		t.Fatalf("121 should be clear after explicit clear")
+	}
+
+	// 100 is even, so the wheel pre-pass should have left it marked.
+	if !sb.isSet(100) {
+		t.Fatalf("100 should start set (it's not a wheel candidate)")
+	}
+}
@@ -0,0 +1,40 @@
+package sieve
+
+import "testing"
+
+func TestWheelResiduesCoprimeTo210(t *testing.T) {
+	seen := map[int64]bool{}
+	for _, r := range wheelResidues {
+		if r <= 0 || r >= wheelModulus {
+			t.Fatalf("residue %d out of range [1, %d)", r, wheelModulus)
+		}
+		if r%2 == 0 || r%3 == 0 || r%5 == 0 || r%7 == 0 {
+			t.Fatalf("residue %d shares a factor with 2, 3, 5 or 7", r)
+		}
+		if seen[r] {
+			t.Fatalf("residue %d listed more than once", r)
+		}
+		seen[r] = true
+	}
+	if len(seen) != len(wheelResidues) {
+		t.Fatalf("expected %d distinct residues, got %d", len(wheelResidues), len(seen))
+	}
+}
+
+func TestWheelResiduesCoverKnownPrimes(t *testing.T) {
+	// Every prime above 7 must be coprime to 2, 3, 5 and 7, so it must
+	// show up as one of the 48 residues mod 210.
+	for _, p := range []int64{11, 13, 17, 19, 23, 29, 31, 37, 41, 199, 211} {
+		r := p % wheelModulus
+		found := false
+		for _, wr := range wheelResidues {
+			if wr == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("prime %d (residue %d) not represented in wheelResidues", p, r)
+		}
+	}
+}